@@ -0,0 +1,151 @@
+// Package errgrpc bridges errors produced by the errkind package (and any
+// error implementing its coder, statusCoder/statuser, publicer or
+// temporaryer interfaces) to and from gRPC status errors.
+//
+// Services that expose both an HTTP and a gRPC API can use ToGRPC and
+// ToNative to share the one error model across both transports: a handler
+// returns the same errkind error regardless of which server is calling it,
+// and the gRPC layer translates it to and from a google.golang.org/grpc/status.Status
+// without losing the code or public message. The HTTP status survives the
+// round trip only for the statuses that have their own gRPC code (400, 403,
+// 404, 409, 429 and 501); any other public error comes back as status 500.
+package errgrpc
+
+import (
+	"net/http"
+
+	"github.com/jjeffery/errkind"
+	"github.com/jjeffery/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// codeKey and messageKey are the field names used in the structpb.Struct
+// detail attached to the gRPC status, so that ToNative can recover the
+// original errkind code without needing a dedicated protobuf message.
+const (
+	codeKey    = "code"
+	messageKey = "message"
+)
+
+// ToGRPC converts err into an error suitable for returning from a gRPC
+// handler. The returned error carries a codes.Code derived from err's HTTP
+// status (see grpcCode), and, when err's cause (as returned by errors.Cause)
+// is public, a detail that preserves the cause's Code() and message so that
+// ToNative can reconstruct a public errkind error with the same message and
+// code on the other side. A public error whose HTTP status has no gRPC code
+// of its own in grpcCode is sent as codes.Internal, and ToNative turns that
+// back into status 500.
+//
+// A nil err returns nil.
+func ToGRPC(err error) error {
+	if err == nil {
+		return nil
+	}
+	cause := errors.Cause(err)
+	code := grpcCode(err)
+	if !errkind.IsPublic(cause) {
+		// Never let a non-public error's message reach the wire: it may
+		// contain implementation details. This mirrors WriteResponse's
+		// handling of non-public errors on the HTTP side.
+		return status.New(code, "internal error").Err()
+	}
+	message := cause.Error()
+	st := status.New(code, message)
+	detail, derr := structpb.NewStruct(map[string]interface{}{
+		messageKey: message,
+		codeKey:    errkind.Code(cause),
+	})
+	if derr != nil {
+		return st.Err()
+	}
+	if stWithDetails, derr := st.WithDetails(detail); derr == nil {
+		st = stWithDetails
+	}
+	return st.Err()
+}
+
+// grpcCode chooses a codes.Code for err based on its HTTP status.
+func grpcCode(err error) codes.Code {
+	switch errkind.Status(err) {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	}
+	if !errkind.IsPublic(errors.Cause(err)) {
+		return codes.Unknown
+	}
+	return codes.Internal
+}
+
+// ToNative converts an error returned from a gRPC call (typically produced
+// by ToGRPC, whether in this process or a remote one) back into an errkind
+// error, so that callers on the gRPC side can use errkind.IsPublic,
+// errkind.HasCode and errkind.Status exactly as they would on the HTTP side.
+//
+// codes.Unavailable and codes.DeadlineExceeded are reported as a
+// errkind.Temporary error. A nil err returns nil. An err that is not a gRPC
+// status error is returned unchanged.
+func ToNative(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch st.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return errkind.Temporary(st.Message())
+	}
+	message, code := st.Message(), ""
+	for _, detail := range st.Details() {
+		s, ok := detail.(*structpb.Struct)
+		if !ok {
+			continue
+		}
+		if v := s.Fields[messageKey]; v != nil {
+			message = v.GetStringValue()
+		}
+		if v := s.Fields[codeKey]; v != nil {
+			code = v.GetStringValue()
+		}
+	}
+	httpStatus, public := httpStatus(st.Code())
+	if !public {
+		return errors.New(message)
+	}
+	return errkind.PublicWithCode(message, httpStatus, code)
+}
+
+// httpStatus reverses grpcCode, reporting whether c was derived from a
+// public errkind error and, if so, the HTTP status it corresponds to.
+func httpStatus(c codes.Code) (status int, public bool) {
+	switch c {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest, true
+	case codes.PermissionDenied:
+		return http.StatusForbidden, true
+	case codes.NotFound:
+		return http.StatusNotFound, true
+	case codes.Aborted:
+		return http.StatusConflict, true
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests, true
+	case codes.Unimplemented:
+		return http.StatusNotImplemented, true
+	case codes.Unknown:
+		return 0, false
+	}
+	return http.StatusInternalServerError, true
+}
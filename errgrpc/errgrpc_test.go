@@ -0,0 +1,87 @@
+package errgrpc
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jjeffery/errkind"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToGRPCPublic(t *testing.T) {
+	err := errkind.PublicWithCode("widget not found", http.StatusNotFound, "widget.missing")
+
+	grpcErr := ToGRPC(err)
+	st, ok := status.FromError(grpcErr)
+	if !ok {
+		t.Fatal("ToGRPC did not return a gRPC status error")
+	}
+	if got, want := st.Code(), codes.NotFound; got != want {
+		t.Errorf("Code() got=%v, want=%v", got, want)
+	}
+	if got, want := st.Message(), "widget not found"; got != want {
+		t.Errorf("Message() got=%v, want=%v", got, want)
+	}
+
+	native := ToNative(grpcErr)
+	if got, want := errkind.Status(native), http.StatusNotFound; got != want {
+		t.Errorf("Status() got=%v, want=%v", got, want)
+	}
+	if got, want := errkind.Code(native), "widget.missing"; got != want {
+		t.Errorf("Code() got=%v, want=%v", got, want)
+	}
+	if got, want := native.Error(), "widget not found"; got != want {
+		t.Errorf("Error() got=%v, want=%v", got, want)
+	}
+}
+
+func TestToGRPCMasksNonPublicMessage(t *testing.T) {
+	// A plain error carries no publicer interface, so its message must
+	// never reach the wire, however innocuous it looks.
+	err := errorString("db password is hunter2")
+
+	grpcErr := ToGRPC(err)
+	st, ok := status.FromError(grpcErr)
+	if !ok {
+		t.Fatal("ToGRPC did not return a gRPC status error")
+	}
+	if st.Message() == string(err) {
+		t.Errorf("ToGRPC leaked non-public message: %v", st.Message())
+	}
+	if got, want := st.Code(), codes.Unknown; got != want {
+		t.Errorf("Code() got=%v, want=%v", got, want)
+	}
+}
+
+type errorString string
+
+func (e errorString) Error() string {
+	return string(e)
+}
+
+func TestToNativeTemporary(t *testing.T) {
+	for _, code := range []codes.Code{codes.Unavailable, codes.DeadlineExceeded} {
+		grpcErr := status.New(code, "upstream down").Err()
+		native := ToNative(grpcErr)
+		if !errkind.IsTemporary(native) {
+			t.Errorf("code %v: IsTemporary() = false, want true", code)
+		}
+	}
+}
+
+func TestToGRPCNil(t *testing.T) {
+	if got := ToGRPC(nil); got != nil {
+		t.Errorf("ToGRPC(nil) = %v, want nil", got)
+	}
+	if got := ToNative(nil); got != nil {
+		t.Errorf("ToNative(nil) = %v, want nil", got)
+	}
+}
+
+func TestToNativeNonStatusError(t *testing.T) {
+	err := errkind.NotFound("widget missing")
+	if got := ToNative(err); got != err {
+		t.Errorf("ToNative(non-status error) = %v, want unchanged", got)
+	}
+}
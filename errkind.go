@@ -38,13 +38,70 @@
 package errkind
 
 import (
+	stderrors "errors"
 	"net/http"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/go-stack/stack"
 	"github.com/jjeffery/errors"
 )
 
+// CaptureStack determines whether the errors constructed by this package
+// (Public, PublicWithCode, Temporary and the HTTP status constructors)
+// capture a stack.CallStack at the point of construction. It defaults to
+// true when running under `go test`, so that test failures are easier to
+// diagnose, and false otherwise. Use WithCaptureStack to override this,
+// for example to enable stack capture in production for 5xx errors.
+//
+// Detection can't rely on flag.Lookup("test.v"): that flag is registered
+// by testing.MainStart, which runs after package-level variables (and
+// therefore this one) are initialized, so it is never found here. Instead
+// this checks for the "<pkg>.test" binary name that `go test` builds.
+var CaptureStack = strings.HasSuffix(os.Args[0], ".test")
+
+// WithCaptureStack sets whether errors constructed from this point on
+// capture a stack trace, returning the previous value so that callers can
+// restore it, typically via defer.
+func WithCaptureStack(capture bool) bool {
+	old := CaptureStack
+	CaptureStack = capture
+	return old
+}
+
+// captureStack returns a pointer to the current call stack, or nil if
+// CaptureStack is false. It returns a pointer rather than a stack.CallStack
+// directly so that the error structs embedding it remain comparable with
+// == -- a slice field would make them uncomparable, which would be a
+// breaking change for any caller that compares errkind errors directly.
+func captureStack() *stack.CallStack {
+	if !CaptureStack {
+		return nil
+	}
+	cs := stack.Trace()
+	return &cs
+}
+
+// StackTraced is implemented by errors that capture the call stack at the
+// point they were constructed.
+type StackTraced interface {
+	StackTrace() stack.CallStack
+}
+
+// AsStackTraced returns err's root cause, as returned by errors.Cause, if
+// that cause implements StackTraced. Unlike HasCode, HasStatus and the
+// other chain-walking predicates, it does not examine the errors in the
+// middle of the chain.
+func AsStackTraced(err error) (StackTraced, bool) {
+	err = errors.Cause(err)
+	if err == nil {
+		return nil, false
+	}
+	st, ok := err.(StackTraced)
+	return st, ok
+}
+
 // cause is an interface implemented by errors that have a cause error.
 type causer interface {
 	Cause() error
@@ -79,13 +136,36 @@ type publicer interface {
 	Public() bool
 }
 
-// HasCode determines whether the error has any of the codes associated with it.
-func HasCode(err error, codes ...string) bool {
-	err = errors.Cause(err)
-	if err == nil {
-		return false
+// retryAfterer is an interface implemented by errors that can report how
+// long a client should wait before retrying. See ServiceUnavailable and
+// GatewayTimeout.
+type retryAfterer interface {
+	RetryAfter() time.Duration
+}
+
+// nextInChain returns the next error to examine when walking err's chain:
+// the result of Cause() if err implements causer, falling back to the
+// standard library's Unwrap so that fmt.Errorf("...: %w", ...) chains are
+// also understood. It returns nil once the chain is exhausted.
+func nextInChain(err error) error {
+	if c, ok := err.(causer); ok {
+		if cause := c.Cause(); cause != nil {
+			return cause
+		}
 	}
-	if errCoder, ok := err.(coder); ok {
+	return stderrors.Unwrap(err)
+}
+
+// HasCode determines whether any error in err's chain has any of the
+// codes associated with it. A middle wrapper that does not implement
+// coder does not stop the search; every error in the chain is checked,
+// from err itself down to its ultimate cause.
+func HasCode(err error, codes ...string) bool {
+	for e := err; e != nil; e = nextInChain(e) {
+		errCoder, ok := e.(coder)
+		if !ok {
+			continue
+		}
 		errCode := errCoder.Code()
 		for _, code := range codes {
 			if errCode == code {
@@ -96,56 +176,56 @@ func HasCode(err error, codes ...string) bool {
 	return false
 }
 
-// HasStatus determines whether the error has any of the statuses associated with it.
+// HasStatus determines whether any error in err's chain has any of the
+// statuses associated with it.
 func HasStatus(err error, statuses ...int) bool {
-	err = errors.Cause(err)
-	if err == nil {
-		return false
-	}
-	if errStatusCoder, ok := err.(statusCoder); ok {
-		errStatus := errStatusCoder.StatusCode()
-		for _, status := range statuses {
-			if errStatus == status {
-				return true
-			}
+	for e := err; e != nil; e = nextInChain(e) {
+		if errStatusCoder, ok := e.(statusCoder); ok && hasInt(errStatusCoder.StatusCode(), statuses) {
+			return true
+		}
+		if errStatuser, ok := e.(statuser); ok && hasInt(errStatuser.Status(), statuses) {
+			return true
 		}
 	}
-	if errStatuser, ok := err.(statuser); ok {
-		errStatus := errStatuser.Status()
-		for _, status := range statuses {
-			if errStatus == status {
-				return true
-			}
+	return false
+}
+
+func hasInt(v int, values []int) bool {
+	for _, want := range values {
+		if v == want {
+			return true
 		}
 	}
 	return false
 }
 
-// Status returns the status code associated with err, or
-// zero if there is no status.
+// Status returns the first non-zero status code found while walking err's
+// chain, or zero if none of the errors in the chain report one.
 func Status(err error) int {
-	err = errors.Cause(err)
-	if err == nil {
-		return 0
-	}
-	if errStatusCoder, ok := err.(statusCoder); ok {
-		return errStatusCoder.StatusCode()
-	}
-	if errStatuser, ok := err.(statuser); ok {
-		return errStatuser.Status()
+	for e := err; e != nil; e = nextInChain(e) {
+		if errStatusCoder, ok := e.(statusCoder); ok {
+			if status := errStatusCoder.StatusCode(); status != 0 {
+				return status
+			}
+		}
+		if errStatuser, ok := e.(statuser); ok {
+			if status := errStatuser.Status(); status != 0 {
+				return status
+			}
+		}
 	}
 	return 0
 }
 
-// Code returns the string error code associated with err, or
-// a blank string if there is no code.
+// Code returns the first non-blank error code found while walking err's
+// chain, or a blank string if none of the errors in the chain report one.
 func Code(err error) string {
-	err = errors.Cause(err)
-	if err == nil {
-		return ""
-	}
-	if errCoder, ok := err.(coder); ok {
-		return errCoder.Code()
+	for e := err; e != nil; e = nextInChain(e) {
+		if errCoder, ok := e.(coder); ok {
+			if code := errCoder.Code(); code != "" {
+				return code
+			}
+		}
 	}
 	return ""
 }
@@ -153,26 +233,26 @@ func Code(err error) string {
 // IsTemporary returns true for errors that indicate
 // an error condition that may succeed if retried.
 //
-// An error is considered temporary if it implements
+// An error is considered temporary if any error in its chain implements
 // the following interface and its Temporary method returns true.
 //  type temporaryer interface {
 //      Temporary() bool
 //  }
 func IsTemporary(err error) bool {
-	err = errors.Cause(err)
-	for err == nil {
-		return false
-	}
-	if temporary, ok := err.(temporaryer); ok {
-		return temporary.Temporary()
+	for e := err; e != nil; e = nextInChain(e) {
+		if temporary, ok := e.(temporaryer); ok && temporary.Temporary() {
+			return true
+		}
 	}
 	return false
 }
 
-// publicStatusError implements error, statusCoder and publicer interfaces.
+// publicStatusError implements error, statusCoder, publicer and
+// StackTraced interfaces.
 type publicStatusError struct {
 	message string
 	status  int
+	stack   *stack.CallStack
 }
 
 func (s publicStatusError) Error() string {
@@ -191,15 +271,47 @@ func (s publicStatusError) Public() bool {
 	return true
 }
 
+func (s publicStatusError) StackTrace() stack.CallStack {
+	if s.stack == nil {
+		return nil
+	}
+	return *s.stack
+}
+
 func (s publicStatusError) With(keyvals ...interface{}) errors.Error {
 	return errors.Wrap(s).With(keyvals...)
 }
 
-// publicStatusCodeError implements error, statusCoder, coder and publicer interfaces.
+// Is reports whether target has the same HTTP status as s, so that
+// errors.Is(err, errkind.ErrNotFound) matches any public error with a
+// status of 404, not just the ErrNotFound value itself.
+func (s publicStatusError) Is(target error) bool {
+	t, ok := target.(statusCoder)
+	return ok && t.StatusCode() == s.status
+}
+
+// As populates *target (a *HTTPError) with s's fields, for use with
+// errors.As.
+func (s publicStatusError) As(target interface{}) bool {
+	httpErr, ok := target.(*HTTPError)
+	if !ok {
+		return false
+	}
+	*httpErr = HTTPError{
+		Message: s.message,
+		Status:  s.status,
+		Public:  true,
+	}
+	return true
+}
+
+// publicStatusCodeError implements error, statusCoder, coder, publicer and
+// StackTraced interfaces.
 type publicStatusCodeError struct {
 	message string
 	status  int
 	code    string
+	stack   *stack.CallStack
 }
 
 func (s publicStatusCodeError) Error() string {
@@ -222,10 +334,60 @@ func (s publicStatusCodeError) Public() bool {
 	return true
 }
 
+func (s publicStatusCodeError) StackTrace() stack.CallStack {
+	if s.stack == nil {
+		return nil
+	}
+	return *s.stack
+}
+
 func (s publicStatusCodeError) With(keyvals ...interface{}) errors.Error {
 	return errors.Wrap(s).With(keyvals...)
 }
 
+// Is reports whether target has the same HTTP status as s, so that
+// errors.Is(err, errkind.ErrBadRequest) matches any public error with a
+// status of 400, regardless of its message or code.
+func (s publicStatusCodeError) Is(target error) bool {
+	t, ok := target.(statusCoder)
+	return ok && t.StatusCode() == s.status
+}
+
+// As populates *target (a *HTTPError) with s's fields, for use with
+// errors.As.
+func (s publicStatusCodeError) As(target interface{}) bool {
+	httpErr, ok := target.(*HTTPError)
+	if !ok {
+		return false
+	}
+	*httpErr = HTTPError{
+		Message: s.message,
+		Status:  s.status,
+		Code:    s.code,
+		Public:  true,
+	}
+	return true
+}
+
+// HTTPError is a concrete error type that exposes the fields of a public
+// errkind error directly, for callers that want field access rather than
+// calling Status, Code and IsPublic individually. Use errors.As to obtain
+// one:
+//  var httpErr errkind.HTTPError
+//  if errors.As(err, &httpErr) {
+//      // httpErr.Status, httpErr.Code, httpErr.Message, httpErr.Public
+//  }
+type HTTPError struct {
+	Message string
+	Status  int
+	Code    string
+	Public  bool
+}
+
+func (e HTTPError) Error() string {
+	return e.Message
+}
+
 // makeMessage returns a string message based on a default message,
 // and zero or more strings in the msg slice. If there is one or more
 // non-blank messages in the msg slice, then they are concatenated and
@@ -260,6 +422,7 @@ func Public(message string, status int) errors.Error {
 	return publicStatusError{
 		message: message,
 		status:  status,
+		stack:   captureStack(),
 	}
 }
 
@@ -284,6 +447,7 @@ func PublicWithCode(message string, status int, code string) errors.Error {
 		message: message,
 		status:  status,
 		code:    code,
+		stack:   captureStack(),
 	}
 }
 
@@ -306,6 +470,11 @@ func PublicWithCode(message string, status int, code string) errors.Error {
 //  if errkind.IsPublic(err) {
 //      // ... can provide err.Error() to the client
 //  }
+//
+// Unlike HasCode, HasStatus and IsTemporary, IsPublic deliberately checks
+// err itself only, rather than walking its chain. Public-ness must not be
+// inherited from a wrapped cause, because the wrapper may carry key/value
+// pairs or other context that is not safe to show to a client.
 func IsPublic(err error) bool {
 	if public, ok := err.(publicer); ok {
 		return public.Public()
@@ -327,26 +496,294 @@ func Forbidden(msg ...string) errors.Error {
 	return Public(makeMessage("forbidden", msg), http.StatusForbidden)
 }
 
+// Unauthorized returns an error that has a status of 401 (unauthorized).
+// The optional msg should not contain sensitive implementation details, as it
+// may be returned to the requesting client.
+func Unauthorized(msg ...string) errors.Error {
+	return Public(makeMessage("unauthorized", msg), http.StatusUnauthorized)
+}
+
+// NotFound returns an error that has a status of 404 (not found).
+// The optional msg should not contain sensitive implementation details, as it
+// may be returned to the requesting client.
+func NotFound(msg ...string) errors.Error {
+	return Public(makeMessage("not found", msg), http.StatusNotFound)
+}
+
+// MethodNotAllowed returns an error that has a status of 405 (method not allowed).
+// The optional msg should not contain sensitive implementation details, as it
+// may be returned to the requesting client.
+func MethodNotAllowed(msg ...string) errors.Error {
+	return Public(makeMessage("method not allowed", msg), http.StatusMethodNotAllowed)
+}
+
+// Conflict returns an error that has a status of 409 (conflict).
+// The optional msg should not contain sensitive implementation details, as it
+// may be returned to the requesting client.
+func Conflict(msg ...string) errors.Error {
+	return Public(makeMessage("conflict", msg), http.StatusConflict)
+}
+
+// Gone returns an error that has a status of 410 (gone).
+// The optional msg should not contain sensitive implementation details, as it
+// may be returned to the requesting client.
+func Gone(msg ...string) errors.Error {
+	return Public(makeMessage("gone", msg), http.StatusGone)
+}
+
+// UnprocessableEntity returns an error that has a status of 422 (unprocessable entity).
+// The optional msg should not contain sensitive implementation details, as it
+// may be returned to the requesting client.
+func UnprocessableEntity(msg ...string) errors.Error {
+	return Public(makeMessage("unprocessable entity", msg), http.StatusUnprocessableEntity)
+}
+
 // NotImplemented returns an error with a status of not implemented.
 // The optional msg should not contain sensitive implementation details, as it
 // may be returned to the requesting client.
 func NotImplemented(msg ...string) errors.Error {
-	return Public(makeMessage("not implemented", msg), http.StatusNotImplemented).With(
-		"caller", stack.Caller(1),
-	)
+	return Public(makeMessage("not implemented", msg), http.StatusNotImplemented)
+}
+
+// InternalServerError returns an error that has a status of 500 (internal server error).
+// The optional msg should not contain sensitive implementation details, as it
+// may be returned to the requesting client.
+func InternalServerError(msg ...string) errors.Error {
+	return Public(makeMessage("internal server error", msg), http.StatusInternalServerError)
+}
+
+// BadGateway returns an error that has a status of 502 (bad gateway).
+// The optional msg should not contain sensitive implementation details, as it
+// may be returned to the requesting client.
+func BadGateway(msg ...string) errors.Error {
+	return Public(makeMessage("bad gateway", msg), http.StatusBadGateway)
+}
+
+// temporaryStatusError implements error, statusCoder, publicer,
+// temporaryer and StackTraced interfaces.
+type temporaryStatusError struct {
+	message string
+	status  int
+	stack   *stack.CallStack
+}
+
+func (s temporaryStatusError) Error() string {
+	return s.message
+}
+
+func (s temporaryStatusError) StatusCode() int {
+	return s.status
+}
+
+func (s temporaryStatusError) Status() int {
+	return s.status
+}
+
+func (s temporaryStatusError) Public() bool {
+	return true
+}
+
+func (s temporaryStatusError) Temporary() bool {
+	return true
+}
+
+func (s temporaryStatusError) StackTrace() stack.CallStack {
+	if s.stack == nil {
+		return nil
+	}
+	return *s.stack
+}
+
+func (s temporaryStatusError) With(keyvals ...interface{}) errors.Error {
+	return errors.Wrap(s).With(keyvals...)
+}
+
+// Is reports whether target has the same HTTP status as s, so that
+// errors.Is(err, errkind.ErrTooManyRequests) matches any temporary error
+// with a status of 429, regardless of its message.
+func (s temporaryStatusError) Is(target error) bool {
+	t, ok := target.(statusCoder)
+	return ok && t.StatusCode() == s.status
+}
+
+// As populates *target (a *HTTPError) with s's fields, for use with
+// errors.As.
+func (s temporaryStatusError) As(target interface{}) bool {
+	httpErr, ok := target.(*HTTPError)
+	if !ok {
+		return false
+	}
+	*httpErr = HTTPError{
+		Message: s.message,
+		Status:  s.status,
+		Public:  true,
+	}
+	return true
+}
+
+// TooManyRequests returns an error that has a status of 429 (too many requests).
+// It implements the temporaryer interface, so IsTemporary returns true for it.
+// The optional msg should not contain sensitive implementation details, as it
+// may be returned to the requesting client.
+func TooManyRequests(msg ...string) errors.Error {
+	return temporaryStatusError{
+		message: makeMessage("too many requests", msg),
+		status:  http.StatusTooManyRequests,
+		stack:   captureStack(),
+	}
+}
+
+// retryableStatusError implements error, statusCoder, publicer,
+// temporaryer, retryAfterer and StackTraced interfaces. Its
+// WithRetryAfter builder returns a new error with the given duration
+// attached.
+type retryableStatusError struct {
+	message    string
+	status     int
+	retryAfter time.Duration
+	stack      *stack.CallStack
 }
 
-type temporaryError string
+func (s retryableStatusError) Error() string {
+	return s.message
+}
+
+func (s retryableStatusError) StatusCode() int {
+	return s.status
+}
+
+func (s retryableStatusError) Status() int {
+	return s.status
+}
+
+func (s retryableStatusError) Public() bool {
+	return true
+}
+
+func (s retryableStatusError) Temporary() bool {
+	return true
+}
+
+func (s retryableStatusError) RetryAfter() time.Duration {
+	return s.retryAfter
+}
+
+func (s retryableStatusError) StackTrace() stack.CallStack {
+	if s.stack == nil {
+		return nil
+	}
+	return *s.stack
+}
+
+// WithRetryAfter returns a new error, identical to s, with the duration
+// that a client should wait before retrying attached. The value is
+// retrieved via the retryAfterer interface, for example by WriteResponse
+// when setting the Retry-After header.
+func (s retryableStatusError) WithRetryAfter(d time.Duration) errors.Error {
+	s.retryAfter = d
+	return s
+}
+
+func (s retryableStatusError) With(keyvals ...interface{}) errors.Error {
+	return errors.Wrap(s).With(keyvals...)
+}
+
+// Is reports whether target has the same HTTP status as s, so that
+// errors.Is(err, errkind.ErrServiceUnavailable) matches any retryable
+// error with the same status, regardless of its message or RetryAfter.
+func (s retryableStatusError) Is(target error) bool {
+	t, ok := target.(statusCoder)
+	return ok && t.StatusCode() == s.status
+}
+
+// As populates *target (a *HTTPError) with s's fields, for use with
+// errors.As.
+func (s retryableStatusError) As(target interface{}) bool {
+	httpErr, ok := target.(*HTTPError)
+	if !ok {
+		return false
+	}
+	*httpErr = HTTPError{
+		Message: s.message,
+		Status:  s.status,
+		Public:  true,
+	}
+	return true
+}
+
+// ServiceUnavailable returns an error that has a status of 503 (service
+// unavailable). It implements the temporaryer interface, so IsTemporary
+// returns true for it, and its WithRetryAfter method can be used to
+// indicate how long a client should wait before retrying.
+// The optional msg should not contain sensitive implementation details, as it
+// may be returned to the requesting client.
+func ServiceUnavailable(msg ...string) errors.Error {
+	return retryableStatusError{
+		message: makeMessage("service unavailable", msg),
+		status:  http.StatusServiceUnavailable,
+		stack:   captureStack(),
+	}
+}
+
+// GatewayTimeout returns an error that has a status of 504 (gateway
+// timeout). It implements the temporaryer interface, so IsTemporary
+// returns true for it, and its WithRetryAfter method can be used to
+// indicate how long a client should wait before retrying.
+// The optional msg should not contain sensitive implementation details, as it
+// may be returned to the requesting client.
+func GatewayTimeout(msg ...string) errors.Error {
+	return retryableStatusError{
+		message: makeMessage("gateway timeout", msg),
+		status:  http.StatusGatewayTimeout,
+		stack:   captureStack(),
+	}
+}
+
+// Sentinel error values for the common HTTP statuses, for use with the
+// standard library's errors.Is, for example
+// errors.Is(err, errkind.ErrNotFound).
+var (
+	ErrBadRequest          = Public("bad request", http.StatusBadRequest)
+	ErrUnauthorized        = Public("unauthorized", http.StatusUnauthorized)
+	ErrForbidden           = Public("forbidden", http.StatusForbidden)
+	ErrNotFound            = Public("not found", http.StatusNotFound)
+	ErrMethodNotAllowed    = Public("method not allowed", http.StatusMethodNotAllowed)
+	ErrConflict            = Public("conflict", http.StatusConflict)
+	ErrGone                = Public("gone", http.StatusGone)
+	ErrUnprocessableEntity = Public("unprocessable entity", http.StatusUnprocessableEntity)
+	ErrInternalServerError = Public("internal server error", http.StatusInternalServerError)
+	ErrNotImplemented      = Public("not implemented", http.StatusNotImplemented)
+	ErrBadGateway          = Public("bad gateway", http.StatusBadGateway)
+	ErrTooManyRequests     = TooManyRequests()
+	ErrServiceUnavailable  = ServiceUnavailable()
+	ErrGatewayTimeout      = GatewayTimeout()
+)
+
+// temporaryError implements error, temporaryer and StackTraced interfaces.
+type temporaryError struct {
+	message string
+	stack   *stack.CallStack
+}
 
 func (t temporaryError) Error() string {
-	return string(t)
+	return t.message
 }
 
 func (t temporaryError) Temporary() bool {
 	return true
 }
 
+func (t temporaryError) StackTrace() stack.CallStack {
+	if t.stack == nil {
+		return nil
+	}
+	return *t.stack
+}
+
 // Temporary returns an error that indicates it is temporary.
 func Temporary(msg string) errors.Error {
-	return errors.Wrap(temporaryError(msg))
+	return errors.Wrap(temporaryError{
+		message: msg,
+		stack:   captureStack(),
+	})
 }
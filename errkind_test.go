@@ -1,7 +1,10 @@
 package errkind
 
 import (
+	stderrors "errors"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/jjeffery/errors"
 )
@@ -255,3 +258,227 @@ func TestPublic(t *testing.T) {
 		}
 	}
 }
+
+func TestStatusConstructors(t *testing.T) {
+	tests := []struct {
+		name        string
+		constructor func(msg ...string) errors.Error
+		wantStatus  int
+		wantDefault string
+	}{
+		{"Unauthorized", Unauthorized, http.StatusUnauthorized, "unauthorized"},
+		{"NotFound", NotFound, http.StatusNotFound, "not found"},
+		{"MethodNotAllowed", MethodNotAllowed, http.StatusMethodNotAllowed, "method not allowed"},
+		{"Conflict", Conflict, http.StatusConflict, "conflict"},
+		{"Gone", Gone, http.StatusGone, "gone"},
+		{"UnprocessableEntity", UnprocessableEntity, http.StatusUnprocessableEntity, "unprocessable entity"},
+		{"InternalServerError", InternalServerError, http.StatusInternalServerError, "internal server error"},
+		{"BadGateway", BadGateway, http.StatusBadGateway, "bad gateway"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.constructor()
+			if got, want := Status(err), tt.wantStatus; got != want {
+				t.Errorf("Status() got=%v, want=%v", got, want)
+			}
+			if got, want := err.Error(), tt.wantDefault; got != want {
+				t.Errorf("Error() got=%v, want=%v", got, want)
+			}
+			if !IsPublic(err) {
+				t.Error("IsPublic() = false, want true")
+			}
+			custom := tt.constructor("custom message")
+			if got, want := custom.Error(), "custom message"; got != want {
+				t.Errorf("Error() with custom msg got=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestTemporaryStatusConstructors(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		err        errors.Error
+		wantStatus int
+	}{
+		{"TooManyRequests", TooManyRequests(), http.StatusTooManyRequests},
+		{"ServiceUnavailable", ServiceUnavailable(), http.StatusServiceUnavailable},
+		{"GatewayTimeout", GatewayTimeout(), http.StatusGatewayTimeout},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if !IsTemporary(tt.err) {
+				t.Error("IsTemporary() = false, want true")
+			}
+			if got, want := Status(tt.err), tt.wantStatus; got != want {
+				t.Errorf("Status() got=%v, want=%v", got, want)
+			}
+			if !IsPublic(tt.err) {
+				t.Error("IsPublic() = false, want true")
+			}
+
+			var httpErr HTTPError
+			if !stderrors.As(tt.err, &httpErr) {
+				t.Fatal("errors.As(err, &httpErr) = false, want true")
+			}
+			if httpErr.Status != tt.wantStatus {
+				t.Errorf("HTTPError.Status got=%v, want=%v", httpErr.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	err := ServiceUnavailable().(retryableStatusError)
+	if got := err.RetryAfter(); got != 0 {
+		t.Errorf("RetryAfter() before WithRetryAfter got=%v, want=0", got)
+	}
+	withRetry := err.WithRetryAfter(10 * time.Second)
+	if got, want := withRetry.(retryableStatusError).RetryAfter(), 10*time.Second; got != want {
+		t.Errorf("RetryAfter() got=%v, want=%v", got, want)
+	}
+	if got, want := Status(withRetry), http.StatusServiceUnavailable; got != want {
+		t.Errorf("Status() got=%v, want=%v", got, want)
+	}
+}
+
+func TestCaptureStackDefault(t *testing.T) {
+	// Under `go test` the binary name ends in ".test", so CaptureStack
+	// should default to true.
+	if !CaptureStack {
+		t.Error("CaptureStack = false, want true under go test")
+	}
+}
+
+func TestWithCaptureStack(t *testing.T) {
+	defer WithCaptureStack(WithCaptureStack(true))
+
+	old := WithCaptureStack(true)
+	if !old {
+		t.Errorf("WithCaptureStack(true) returned %v, want true", old)
+	}
+	if err := Public("boom", 500); err.(StackTraced).StackTrace() == nil {
+		t.Error("StackTrace() = nil, want non-nil with CaptureStack true")
+	}
+
+	old = WithCaptureStack(false)
+	if !old {
+		t.Errorf("WithCaptureStack(false) returned %v, want true", old)
+	}
+	if err := Public("boom", 500); err.(StackTraced).StackTrace() != nil {
+		t.Error("StackTrace() != nil, want nil with CaptureStack false")
+	}
+}
+
+func TestStackTraceOnConstructors(t *testing.T) {
+	defer WithCaptureStack(WithCaptureStack(true))
+
+	tests := []struct {
+		name string
+		err  errors.Error
+	}{
+		{"Public", Public("boom", 500)},
+		{"PublicWithCode", PublicWithCode("boom", 500, "CODE")},
+		{"Temporary", Temporary("boom")},
+		{"TooManyRequests", TooManyRequests()},
+		{"ServiceUnavailable", ServiceUnavailable()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, ok := AsStackTraced(tt.err)
+			if !ok {
+				t.Fatal("AsStackTraced() ok = false, want true")
+			}
+			if st.StackTrace() == nil {
+				t.Error("StackTrace() = nil, want non-nil")
+			}
+		})
+	}
+}
+
+func TestAsStackTraced(t *testing.T) {
+	if _, ok := AsStackTraced(nil); ok {
+		t.Error("AsStackTraced(nil) ok = true, want false")
+	}
+	if _, ok := AsStackTraced(errors.New("plain")); ok {
+		t.Error("AsStackTraced(plain error) ok = true, want false")
+	}
+	if _, ok := AsStackTraced(Public("boom", 500)); !ok {
+		t.Error("AsStackTraced(Public(...)) ok = false, want true")
+	}
+}
+
+func TestChainWalkingThroughWrapper(t *testing.T) {
+	cause := PublicWithCode("backend unavailable", http.StatusServiceUnavailable, "backend.down")
+	wrapped := errors.Wrap(cause, "calling backend").With("attempt", 3)
+
+	if !HasCode(wrapped, "backend.down") {
+		t.Error("HasCode() = false, want true through wrapper")
+	}
+	if !HasStatus(wrapped, http.StatusServiceUnavailable) {
+		t.Error("HasStatus() = false, want true through wrapper")
+	}
+	if got, want := Status(wrapped), http.StatusServiceUnavailable; got != want {
+		t.Errorf("Status() got=%v, want=%v", got, want)
+	}
+	if got, want := Code(wrapped), "backend.down"; got != want {
+		t.Errorf("Code() got=%v, want=%v", got, want)
+	}
+
+	temp := errors.Wrap(TooManyRequests(), "rate limited")
+	if !IsTemporary(temp) {
+		t.Error("IsTemporary() = false, want true through wrapper")
+	}
+}
+
+func TestErrorsAsThroughWrapper(t *testing.T) {
+	cause := PublicWithCode("bad field", http.StatusBadRequest, "input.invalid")
+	wrapped := errors.Wrap(cause, "validating request").With("field", "email")
+
+	var httpErr HTTPError
+	if !stderrors.As(wrapped, &httpErr) {
+		t.Fatal("errors.As(wrapped, &httpErr) = false, want true")
+	}
+	if httpErr.Code != "input.invalid" || httpErr.Status != http.StatusBadRequest || httpErr.Message != "bad field" {
+		t.Errorf("unexpected HTTPError: %+v", httpErr)
+	}
+
+	if !stderrors.Is(wrapped, ErrBadRequest) {
+		t.Error("errors.Is(wrapped, ErrBadRequest) = false, want true")
+	}
+}
+
+func TestSentinels(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      errors.Error
+		sentinel error
+	}{
+		{"BadRequest", BadRequest(), ErrBadRequest},
+		{"Unauthorized", Unauthorized(), ErrUnauthorized},
+		{"Forbidden", Forbidden(), ErrForbidden},
+		{"NotFound", NotFound(), ErrNotFound},
+		{"MethodNotAllowed", MethodNotAllowed(), ErrMethodNotAllowed},
+		{"Conflict", Conflict(), ErrConflict},
+		{"Gone", Gone(), ErrGone},
+		{"UnprocessableEntity", UnprocessableEntity(), ErrUnprocessableEntity},
+		{"InternalServerError", InternalServerError(), ErrInternalServerError},
+		{"NotImplemented", NotImplemented(), ErrNotImplemented},
+		{"BadGateway", BadGateway(), ErrBadGateway},
+		{"TooManyRequests", TooManyRequests(), ErrTooManyRequests},
+		{"ServiceUnavailable", ServiceUnavailable(), ErrServiceUnavailable},
+		{"GatewayTimeout", GatewayTimeout(), ErrGatewayTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !stderrors.Is(tt.err, tt.sentinel) {
+				t.Errorf("errors.Is(%v, %v) = false, want true", tt.err, tt.sentinel)
+			}
+			// A differently worded error with the same status still matches,
+			// since Is compares by status rather than message.
+			custom := Public("a completely different message", Status(tt.err))
+			if !stderrors.Is(custom, tt.sentinel) {
+				t.Errorf("errors.Is(custom, %v) = false, want true", tt.sentinel)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package errkind
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/go-stack/stack"
+	"github.com/jjeffery/errors"
+)
+
+// Kind is a composite, numerically-addressable error code. It is made up of
+// three fields: Scope identifies the service or module that raised the
+// error, Category groups errors by the kind of condition they represent
+// (input, db, auth, system, pubsub, resource, ...), and Detail identifies
+// the specific condition within that category.
+//
+// Kind values are intended to be stable identifiers that survive logging
+// and wire formats, giving users a richer taxonomy than an opaque string
+// code while still round-tripping through the existing coder interface --
+// Kind.String() is used as the Code() of any error constructed with
+// PublicWithKind.
+type Kind struct {
+	Scope    uint16
+	Category uint16
+	Detail   uint16
+}
+
+// NewKind returns a Kind built from the given scope, category and detail.
+func NewKind(scope, category, detail uint16) Kind {
+	return Kind{
+		Scope:    scope,
+		Category: category,
+		Detail:   detail,
+	}
+}
+
+// kinder is an interface implemented by errors that return a Kind.
+type kinder interface {
+	Kind() Kind
+}
+
+var kindNames = struct {
+	mu sync.RWMutex
+	m  map[Kind]string
+}{m: make(map[Kind]string)}
+
+// RegisterKind associates k with name, so that k.String() returns name
+// instead of its default numeric representation. This allows errors to be
+// logged and transmitted using a stable identifier such as
+// "auth.unauthorized" that reads naturally. Registration affects only the
+// string form: HasKind and KindOf compare and return the Kind values
+// themselves, whether or not a name is registered. There is no function
+// that parses a Kind back from its string form.
+func RegisterKind(k Kind, name string) {
+	kindNames.mu.Lock()
+	defer kindNames.mu.Unlock()
+	kindNames.m[k] = name
+}
+
+// String returns the registered name for k, if any, such as
+// "auth.unauthorized". Otherwise it returns a formatted numeric
+// representation such as "03-05-002".
+func (k Kind) String() string {
+	kindNames.mu.RLock()
+	name, ok := kindNames.m[k]
+	kindNames.mu.RUnlock()
+	if ok {
+		return name
+	}
+	return fmt.Sprintf("%02d-%02d-%03d", k.Scope, k.Category, k.Detail)
+}
+
+// HasKind determines whether any error in err's chain has any of the
+// kinds associated with it, walking the chain the same way as HasCode and
+// HasStatus so that a middle wrapper implementing kinder is not missed.
+func HasKind(err error, kinds ...Kind) bool {
+	for e := err; e != nil; e = nextInChain(e) {
+		errKinder, ok := e.(kinder)
+		if !ok {
+			continue
+		}
+		errKind := errKinder.Kind()
+		for _, k := range kinds {
+			if errKind == k {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// KindOf returns the first Kind found while walking err's chain, or the
+// zero Kind if none of the errors in the chain report one.
+func KindOf(err error) Kind {
+	for e := err; e != nil; e = nextInChain(e) {
+		if errKinder, ok := e.(kinder); ok {
+			return errKinder.Kind()
+		}
+	}
+	return Kind{}
+}
+
+// publicStatusKindError implements error, statusCoder, coder, kinder,
+// publicer and StackTraced interfaces.
+type publicStatusKindError struct {
+	message string
+	status  int
+	kind    Kind
+	stack   *stack.CallStack
+}
+
+func (s publicStatusKindError) Error() string {
+	return s.message
+}
+
+func (s publicStatusKindError) StatusCode() int {
+	return s.status
+}
+
+func (s publicStatusKindError) Status() int {
+	return s.status
+}
+
+func (s publicStatusKindError) Code() string {
+	return s.kind.String()
+}
+
+func (s publicStatusKindError) Kind() Kind {
+	return s.kind
+}
+
+func (s publicStatusKindError) Public() bool {
+	return true
+}
+
+func (s publicStatusKindError) StackTrace() stack.CallStack {
+	if s.stack == nil {
+		return nil
+	}
+	return *s.stack
+}
+
+func (s publicStatusKindError) With(keyvals ...interface{}) errors.Error {
+	return errors.Wrap(s).With(keyvals...)
+}
+
+// Is reports whether target has the same HTTP status as s, so that
+// errors.Is(err, errkind.ErrNotFound) matches a PublicWithKind error with
+// a status of 404 just as it matches one built with Public or
+// PublicWithCode.
+func (s publicStatusKindError) Is(target error) bool {
+	t, ok := target.(statusCoder)
+	return ok && t.StatusCode() == s.status
+}
+
+// As populates *target (a *HTTPError) with s's fields, for use with
+// errors.As.
+func (s publicStatusKindError) As(target interface{}) bool {
+	httpErr, ok := target.(*HTTPError)
+	if !ok {
+		return false
+	}
+	*httpErr = HTTPError{
+		Message: s.message,
+		Status:  s.status,
+		Code:    s.kind.String(),
+		Public:  true,
+	}
+	return true
+}
+
+// PublicWithKind returns an error with the message, status and Kind k.
+// Its Code() method returns k.String(), so HasCode and Code continue to
+// work with string codes such as "auth.unauthorized", while HasKind and
+// KindOf give access to the structured Scope/Category/Detail taxonomy.
+//
+// The message should not contain any implementation details as it may be
+// displayed to a requesting client.
+func PublicWithKind(message string, status int, k Kind) errors.Error {
+	return publicStatusKindError{
+		message: message,
+		status:  status,
+		kind:    k,
+		stack:   captureStack(),
+	}
+}
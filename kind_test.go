@@ -0,0 +1,109 @@
+package errkind
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"github.com/jjeffery/errors"
+)
+
+func TestKindString(t *testing.T) {
+	k := NewKind(3, 5, 2)
+	if got, want := k.String(), "03-05-002"; got != want {
+		t.Errorf("got=%v, want=%v", got, want)
+	}
+
+	RegisterKind(k, "auth.unauthorized")
+	if got, want := k.String(), "auth.unauthorized"; got != want {
+		t.Errorf("got=%v, want=%v", got, want)
+	}
+
+	other := NewKind(3, 5, 3)
+	if got, want := other.String(), "03-05-003"; got != want {
+		t.Errorf("got=%v, want=%v", got, want)
+	}
+}
+
+func TestHasKindAndKindOf(t *testing.T) {
+	k := NewKind(1, 2, 3)
+	notFound := NewKind(4, 4, 4)
+
+	tests := []struct {
+		err      error
+		kinds    []Kind
+		want     bool
+		wantKind Kind
+	}{
+		{
+			err:      nil,
+			kinds:    []Kind{k},
+			want:     false,
+			wantKind: Kind{},
+		},
+		{
+			err:      PublicWithKind("test error", 400, k),
+			kinds:    []Kind{notFound},
+			want:     false,
+			wantKind: k,
+		},
+		{
+			err:      PublicWithKind("test error", 400, k),
+			kinds:    []Kind{notFound, k},
+			want:     true,
+			wantKind: k,
+		},
+		{
+			// HasKind and KindOf must walk past a middle wrapper that
+			// does not itself implement kinder.
+			err:      errors.Wrap(PublicWithKind("test error", 400, k), "wrapped").With("a", "b"),
+			kinds:    []Kind{k},
+			want:     true,
+			wantKind: k,
+		},
+		{
+			err:      errors.New("no kind"),
+			kinds:    []Kind{k},
+			want:     false,
+			wantKind: Kind{},
+		},
+	}
+	for i, tt := range tests {
+		if got, want := HasKind(tt.err, tt.kinds...), tt.want; got != want {
+			t.Errorf("%d: want=%v, got=%v", i, want, got)
+		}
+		if got, want := KindOf(tt.err), tt.wantKind; got != want {
+			t.Errorf("%d: want=%v, got=%v", i, want, got)
+		}
+	}
+}
+
+func TestPublicWithKind(t *testing.T) {
+	k := NewKind(1, 2, 3)
+	RegisterKind(k, "input.invalid")
+
+	err := PublicWithKind("bad field", 400, k)
+	if got, want := err.Error(), "bad field"; got != want {
+		t.Errorf("Error() got=%v, want=%v", got, want)
+	}
+	if got, want := Status(err), 400; got != want {
+		t.Errorf("Status() got=%v, want=%v", got, want)
+	}
+	if got, want := Code(err), "input.invalid"; got != want {
+		t.Errorf("Code() got=%v, want=%v", got, want)
+	}
+	if !IsPublic(err) {
+		t.Error("IsPublic() = false, want true")
+	}
+
+	if !stderrors.Is(err, ErrBadRequest) {
+		t.Error("errors.Is(err, ErrBadRequest) = false, want true")
+	}
+
+	var httpErr HTTPError
+	if !stderrors.As(err, &httpErr) {
+		t.Fatal("errors.As(err, &httpErr) = false, want true")
+	}
+	if httpErr.Code != "input.invalid" || httpErr.Status != 400 || httpErr.Message != "bad field" {
+		t.Errorf("unexpected HTTPError: %+v", httpErr)
+	}
+}
@@ -0,0 +1,121 @@
+package errkind
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jjeffery/errors"
+)
+
+// defaultRetryAfter is the Retry-After value written for a temporary error
+// that does not implement retryAfterer, or whose RetryAfter() is zero.
+const defaultRetryAfter = 30 * time.Second
+
+// retryAfter returns the first positive duration reported by a retryAfterer
+// found while walking err's chain, or defaultRetryAfter if none is found.
+func retryAfter(err error) time.Duration {
+	for e := err; e != nil; e = nextInChain(e) {
+		if r, ok := e.(retryAfterer); ok {
+			if d := r.RetryAfter(); d > 0 {
+				return d
+			}
+		}
+	}
+	return defaultRetryAfter
+}
+
+// responseBody is the JSON shape used by both FromResponse and
+// WriteResponse to carry a public error across an HTTP response.
+type responseBody struct {
+	Message string `json:"message"`
+	Code    string `json:"code,omitempty"`
+	Status  int    `json:"status,omitempty"`
+}
+
+// FromResponse constructs an error from a failed HTTP response, so that a
+// client calling another service can report the same kind of error that
+// the service itself would have returned from WriteResponse.
+//
+// If resp.StatusCode indicates success (less than 400), FromResponse
+// returns nil. Otherwise it attempts to decode the response body as JSON
+// in the shape written by WriteResponse ({"message": "...", "code": "..."})
+// and returns a PublicWithCode error carrying the response status, code
+// and message. If the body cannot be decoded this way, FromResponse falls
+// back to a Public error built from resp.Status and resp.StatusCode.
+func FromResponse(resp *http.Response) errors.Error {
+	if resp.StatusCode < http.StatusBadRequest {
+		return nil
+	}
+	var body responseBody
+	if resp.Body == nil {
+		return Public(resp.Status, resp.StatusCode)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Message == "" {
+		return Public(resp.Status, resp.StatusCode)
+	}
+	return PublicWithCode(body.Message, resp.StatusCode, body.Code)
+}
+
+// WriteResponse writes err to w as a JSON response, in the shape expected
+// by FromResponse.
+//
+// Everything written is taken from err's cause, as returned by
+// errors.Cause. The status written is Status(cause), or 500 if the cause
+// has no status. The message written is the cause's message if
+// IsPublic(cause), or a generic message derived from the status otherwise,
+// so that implementation details in a non-public error are never leaked to
+// the client. The code written is Code(cause). If IsTemporary(cause), a
+// Retry-After header is set so that well-behaved clients back off before
+// retrying, using the duration from the retryAfterer interface (see
+// ServiceUnavailable and GatewayTimeout) if the cause implements it, or
+// defaultRetryAfter otherwise.
+//
+// A nil err writes nothing.
+func WriteResponse(w http.ResponseWriter, err error) {
+	if err == nil {
+		return
+	}
+	cause := errors.Cause(err)
+	status := Status(cause)
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	message := http.StatusText(status)
+	if IsPublic(cause) {
+		message = cause.Error()
+	}
+	if IsTemporary(cause) {
+		seconds := int(retryAfter(cause).Round(time.Second) / time.Second)
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(responseBody{
+		Message: message,
+		Code:    Code(cause),
+		Status:  status,
+	})
+}
+
+// AnnotateFromStatus returns a public error with the given HTTP status,
+// whose message combines msg with cause's own message, and whose Code()
+// preserves cause's Code(), if it has one. This lets a caller bridging to
+// a remote service attach the status that service reported while keeping
+// the underlying error's message and code.
+//
+// The returned error does not wrap cause: it is a new error holding a copy
+// of cause's message and code, and neither errors.Cause nor the standard
+// library's errors.Unwrap reaches cause from it.
+func AnnotateFromStatus(cause error, status int, msg string) errors.Error {
+	message := cause.Error()
+	if msg != "" {
+		message = msg + ": " + message
+	}
+	if code := Code(cause); code != "" {
+		return PublicWithCode(message, status, code)
+	}
+	return Public(message, status)
+}
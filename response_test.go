@@ -0,0 +1,158 @@
+package errkind
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jjeffery/errors"
+)
+
+func newBody(s string) io.ReadCloser {
+	return io.NopCloser(strings.NewReader(s))
+}
+
+func TestFromResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantStatus int
+		wantCode   string
+		wantMsg    string
+	}{
+		{
+			name:   "success",
+			status: http.StatusOK,
+			body:   "",
+		},
+		{
+			name:       "json body",
+			status:     http.StatusNotFound,
+			body:       `{"message":"widget not found","code":"widget.missing"}`,
+			wantStatus: http.StatusNotFound,
+			wantCode:   "widget.missing",
+			wantMsg:    "widget not found",
+		},
+		{
+			name:       "non-json body falls back to resp.Status",
+			status:     http.StatusInternalServerError,
+			body:       "not json",
+			wantStatus: http.StatusInternalServerError,
+			wantMsg:    http.StatusText(http.StatusInternalServerError),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: tt.status,
+				Status:     http.StatusText(tt.status),
+				Body:       newBody(tt.body),
+			}
+			err := FromResponse(resp)
+			if tt.status < http.StatusBadRequest {
+				if err != nil {
+					t.Fatalf("want nil, got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("want non-nil error")
+			}
+			if got := Status(err); got != tt.wantStatus {
+				t.Errorf("Status() got=%v, want=%v", got, tt.wantStatus)
+			}
+			if got := Code(err); got != tt.wantCode {
+				t.Errorf("Code() got=%v, want=%v", got, tt.wantCode)
+			}
+			if tt.wantMsg != "" && err.Error() != tt.wantMsg {
+				t.Errorf("Error() got=%v, want=%v", err.Error(), tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestWriteResponse(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantStatus     int
+		wantMsg        string
+		wantRetryAfter string
+	}{
+		{
+			name: "nothing written for nil",
+			err:  nil,
+		},
+		{
+			name:       "public error",
+			err:        NotFound("widget missing"),
+			wantStatus: http.StatusNotFound,
+			wantMsg:    "widget missing",
+		},
+		{
+			name:       "non-public error message is masked",
+			err:        errors.New("db password is hunter2"),
+			wantStatus: http.StatusInternalServerError,
+			wantMsg:    http.StatusText(http.StatusInternalServerError),
+		},
+		{
+			name:           "temporary error without RetryAfter uses default",
+			err:            TooManyRequests(),
+			wantStatus:     http.StatusTooManyRequests,
+			wantRetryAfter: "30",
+		},
+		{
+			name:           "ServiceUnavailable honors WithRetryAfter",
+			err:            ServiceUnavailable().(retryableStatusError).WithRetryAfter(5 * time.Second),
+			wantStatus:     http.StatusServiceUnavailable,
+			wantRetryAfter: "5",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			WriteResponse(rec, tt.err)
+			if tt.err == nil {
+				if rec.Code != 200 {
+					t.Fatalf("expected no write, got status %v", rec.Code)
+				}
+				return
+			}
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status got=%v, want=%v", rec.Code, tt.wantStatus)
+			}
+			var body responseBody
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("decode response body: %v", err)
+			}
+			if body.Message != tt.wantMsg {
+				t.Errorf("message got=%v, want=%v", body.Message, tt.wantMsg)
+			}
+			if tt.wantRetryAfter != "" {
+				if got := rec.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+					t.Errorf("Retry-After got=%v, want=%v", got, tt.wantRetryAfter)
+				}
+			}
+		})
+	}
+}
+
+func TestAnnotateFromStatus(t *testing.T) {
+	cause := PublicWithCode("upstream failed", 0, "upstream.timeout")
+	err := AnnotateFromStatus(cause, http.StatusBadGateway, "calling widget service")
+
+	if got, want := Status(err), http.StatusBadGateway; got != want {
+		t.Errorf("Status() got=%v, want=%v", got, want)
+	}
+	if got, want := Code(err), "upstream.timeout"; got != want {
+		t.Errorf("Code() got=%v, want=%v", got, want)
+	}
+	if !strings.Contains(err.Error(), "upstream failed") {
+		t.Errorf("Error() = %v, want it to contain the cause's message", err.Error())
+	}
+}